@@ -0,0 +1,67 @@
+package cast
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/barnybug/go-cast/net"
+)
+
+// Session represents a running receiver app instance, as returned by
+// LaunchApp. It's the handle callers use to open namespace channels against
+// that specific app, rather than the built-in Media/Receiver controllers.
+type Session struct {
+	AppId       string
+	SessionId   string
+	TransportId string
+
+	client *Client
+}
+
+type launchStatus struct {
+	net.PayloadHeaders
+	Status struct {
+		Applications []struct {
+			AppId       string `json:"appId"`
+			SessionId   string `json:"sessionId"`
+			TransportId string `json:"transportId"`
+		} `json:"applications"`
+	} `json:"status"`
+}
+
+// LaunchApp starts the receiver app identified by appId (e.g. "233637DE" for
+// YouTube) and returns a Session once it reports back as running. Unlike
+// Media, which only knows the built-in media receiver, this works for any
+// appId - DashCast, Spotify, custom HDMI-CEC receivers and so on.
+func (c *Client) LaunchApp(ctx context.Context, appId string) (*Session, error) {
+	message, err := c.receiver.Launch(ctx, appId)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &launchStatus{}
+	if err := json.Unmarshal([]byte(*message.PayloadUtf8), status); err != nil {
+		return nil, err
+	}
+
+	for _, app := range status.Status.Applications {
+		if app.AppId == appId {
+			return &Session{
+				AppId:       app.AppId,
+				SessionId:   app.SessionId,
+				TransportId: app.TransportId,
+				client:      c,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cast: app %q did not appear in receiver status after launch", appId)
+}
+
+// Channel opens a channel on namespace against this session's app instance,
+// so callers can drive namespaces this library has no controller for.
+func (s *Session) Channel(namespace string) *net.Channel {
+	return net.NewChannel(s.client.conn, s.client.sourceId, s.TransportId, namespace)
+}
@@ -0,0 +1,177 @@
+// Package cast is a pure-Go client for the Chromecast v2 protocol.
+package cast
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/barnybug/go-cast/controllers"
+	castnet "github.com/barnybug/go-cast/net"
+)
+
+// Version is the library's version, surfaced by the CLI's --version flag.
+var Version = "0.1.0"
+
+const (
+	defaultSender             = "sender-0"
+	defaultReceiver           = "receiver-0"
+	namespaceConnection       = "urn:x-cast:com.google.cast.tp.connection"
+	defaultMediaReceiverAppId = "CC1AD845"
+)
+
+// Client drives a single Chromecast: its receiver (volume, running apps)
+// and, once an app is running, its media session.
+type Client struct {
+	ip   net.IP
+	port int
+
+	conn       *castnet.Connection
+	sourceId   string
+	supervisor *castnet.Supervisor
+
+	mutex    sync.Mutex
+	receiver *controllers.ReceiverController
+	media    *controllers.MediaController
+}
+
+// NewClient builds a Client for the Chromecast at ip:port. Call Connect
+// before using it.
+func NewClient(ip net.IP, port int) *Client {
+	return &Client{
+		ip:       ip,
+		port:     port,
+		sourceId: defaultSender,
+	}
+}
+
+// Connect dials the Chromecast, brings up the receiver channel, and starts
+// the supervisor that transparently reconnects - replaying the CONNECT
+// handshake and any in-flight requests - if the connection drops.
+func (c *Client) Connect(ctx context.Context) error {
+	c.conn = castnet.NewConnection()
+	if err := c.conn.Connect(ctx, c.ip, c.port); err != nil {
+		return err
+	}
+	if err := c.handshake(ctx); err != nil {
+		return err
+	}
+
+	c.receiver = controllers.NewReceiverController(c.conn, c.sourceId, defaultReceiver)
+
+	c.supervisor = castnet.NewSupervisor(c.conn, c.ip, c.port, c.handshake)
+	c.supervisor.Register(c.receiver.Channel())
+	c.conn.OnDisconnect(func(err error) {
+		go c.supervisor.Disconnected(ctx)
+	})
+
+	return nil
+}
+
+// handshake opens the virtual connection to the receiver and, if a media
+// session was already running, restores it. It runs on the initial Connect
+// and is replayed by the supervisor after every reconnect.
+func (c *Client) handshake(ctx context.Context) error {
+	channel := castnet.NewChannel(c.conn, c.sourceId, defaultReceiver, namespaceConnection)
+	if err := channel.Send(&castnet.PayloadHeaders{Type: "CONNECT"}); err != nil {
+		return err
+	}
+	return c.restoreMediaSession(ctx)
+}
+
+// restoreMediaSession re-launches the media receiver app if it's no longer
+// running and re-resolves its transportId after a reconnect. The receiver doesn't promise to hand back
+// the same transportId it had before dropping, so without this,
+// Channel.Resend would replay in-flight media requests against a stale
+// destination and they'd silently never complete.
+func (c *Client) restoreMediaSession(ctx context.Context) error {
+	c.mutex.Lock()
+	media := c.media
+	c.mutex.Unlock()
+	if media == nil {
+		return nil
+	}
+
+	transportId, err := c.runningMediaApp(ctx)
+	if err != nil {
+		return err
+	}
+	if transportId == "" {
+		if _, err := c.receiver.Launch(ctx, defaultMediaReceiverAppId); err != nil {
+			return err
+		}
+		if transportId, err = c.runningMediaApp(ctx); err != nil {
+			return err
+		}
+	}
+	if transportId == "" {
+		return fmt.Errorf("cast: no media receiver application running after reconnect")
+	}
+
+	connectChannel := castnet.NewChannel(c.conn, c.sourceId, transportId, namespaceConnection)
+	if err := connectChannel.Send(&castnet.PayloadHeaders{Type: "CONNECT"}); err != nil {
+		return err
+	}
+
+	media.Channel().DestinationId = transportId
+	return nil
+}
+
+// Receiver returns the controller for the Chromecast's receiver (volume,
+// running applications).
+func (c *Client) Receiver() *controllers.ReceiverController {
+	return c.receiver
+}
+
+// Media returns the controller for the currently running media session,
+// launching the default media receiver app first if it isn't running.
+func (c *Client) Media(ctx context.Context) (*controllers.MediaController, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.media != nil {
+		return c.media, nil
+	}
+
+	transportId, err := c.runningMediaApp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if transportId == "" {
+		if _, err := c.receiver.Launch(ctx, defaultMediaReceiverAppId); err != nil {
+			return nil, err
+		}
+		if transportId, err = c.runningMediaApp(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if transportId == "" {
+		return nil, fmt.Errorf("cast: no media receiver application running")
+	}
+
+	media := controllers.NewMediaController(c.conn, c.sourceId, transportId)
+	c.supervisor.Register(media.Channel())
+	c.media = media
+	return media, nil
+}
+
+func (c *Client) runningMediaApp(ctx context.Context) (string, error) {
+	status, err := c.receiver.GetStatus(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, app := range status.Applications {
+		if app.AppId == defaultMediaReceiverAppId {
+			return app.TransportId, nil
+		}
+	}
+	return "", nil
+}
+
+// IsPlaying reports whether a media session is currently active.
+func (c *Client) IsPlaying(ctx context.Context) bool {
+	transportId, err := c.runningMediaApp(ctx)
+	return err == nil && transportId != ""
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -13,7 +15,10 @@ import (
 
 	"github.com/barnybug/go-cast"
 	"github.com/barnybug/go-cast/controllers"
+	"github.com/barnybug/go-cast/discovery"
+	"github.com/barnybug/go-cast/group"
 	"github.com/barnybug/go-cast/log"
+	"github.com/barnybug/go-cast/mediaserver"
 	"github.com/codegangsta/cli"
 )
 
@@ -33,7 +38,11 @@ func main() {
 		},
 		cli.StringFlag{
 			Name:  "host",
-			Usage: "chromecast hostname or IP (required)",
+			Usage: "chromecast hostname or IP",
+		},
+		cli.StringFlag{
+			Name:  "name",
+			Usage: "chromecast friendly name, looked up via mDNS (alternative to --host)",
 		},
 		cli.IntFlag{
 			Name:  "port",
@@ -77,6 +86,12 @@ func main() {
 			Usage:  "close current app on Chromecast",
 			Action: cliCommand,
 		},
+		{
+			Name:      "queue",
+			Usage:     "manage the playback queue",
+			ArgsUsage: "queue add/list/next/prev/shuffle [urls...]",
+			Action:    cliCommand,
+		},
 		{
 			Name:   "script",
 			Usage:  "Run the set of commands passed to stdin",
@@ -87,6 +102,23 @@ func main() {
 			Usage:  "Get status of the Chromecast",
 			Action: statusCommand,
 		},
+		{
+			Name:      "group",
+			Usage:     "drive several Chromecasts as one, with synchronized playback",
+			ArgsUsage: "group --hosts a,b,c play url",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "hosts",
+					Usage: "comma-separated chromecast hostnames or IPs",
+				},
+			},
+			Action: groupCommand,
+		},
+		{
+			Name:   "discover",
+			Usage:  "List Chromecasts found on the local network",
+			Action: discoverCommand,
+		},
 	}
 	app.Run(os.Args)
 	log.Println("Done")
@@ -99,22 +131,104 @@ func cliCommand(c *cli.Context) {
 	if !checkCommand(c.Command.Name, c.Args()) {
 		return
 	}
-	client := connect(ctx, c)
-	runCommand(ctx, client, c.Command.Name, c.Args())
+	client, ip := connect(ctx, c)
+	runCommand(ctx, client, ip, c.Command.Name, c.Args())
 }
 
-func connect(ctx context.Context, c *cli.Context) *cast.Client {
+func connect(ctx context.Context, c *cli.Context) (*cast.Client, net.IP) {
 	host := c.GlobalString("host")
-	log.Printf("Looking up %s...", host)
-	ips, err := net.LookupIP(host)
-	checkErr(err)
+	name := c.GlobalString("name")
+	port := c.GlobalInt("port")
 
-	client := cast.NewClient(ips[0], c.GlobalInt("port"))
-	err = client.Connect(ctx)
+	var ip net.IP
+	if host == "" && name != "" {
+		log.Printf("Looking up Chromecast named %q...", name)
+		device, err := discovery.Find(ctx, name, 5*time.Second)
+		checkErr(err)
+		ip = device.IP
+		port = device.Port
+	} else {
+		log.Printf("Looking up %s...", host)
+		ips, err := net.LookupIP(host)
+		checkErr(err)
+		ip = ips[0]
+	}
+
+	client := cast.NewClient(ip, port)
+	err := client.Connect(ctx)
 	checkErr(err)
 
 	log.Println("Connected")
-	return client
+	return client, ip
+}
+
+func groupCommand(c *cli.Context) {
+	log.Debug = c.GlobalBool("debug")
+	ctx, cancel := context.WithTimeout(context.Background(), c.GlobalDuration("timeout"))
+	defer cancel()
+
+	hosts := strings.Split(c.String("hosts"), ",")
+	if len(hosts) == 0 || hosts[0] == "" {
+		fmt.Println("Command 'group' requires --hosts")
+		return
+	}
+
+	clients := make([]*cast.Client, len(hosts))
+	for i, host := range hosts {
+		ips, err := net.LookupIP(strings.TrimSpace(host))
+		checkErr(err)
+		clients[i] = cast.NewClient(ips[0], c.GlobalInt("port"))
+		checkErr(clients[i].Connect(ctx))
+	}
+	g := group.New(clients...)
+
+	args := c.Args()
+	if len(args) == 0 {
+		fmt.Println("Command 'group' requires an action, e.g. play <url>")
+		return
+	}
+
+	switch args[0] {
+	case "play":
+		if len(args) < 2 {
+			fmt.Println("Command 'group play' requires a URL")
+			return
+		}
+		item := controllers.MediaItem{args[1], "BUFFERED", "audio/mpeg"}
+		checkErr(g.Play(ctx, item))
+
+		// Play's drift-correction loop keeps running in the background for
+		// as long as the group exists; block here so it actually gets to
+		// run instead of the process exiting the instant Play returns.
+		fmt.Println("Synchronized playback started - press Ctrl+C to stop")
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		<-sig
+		g.Close()
+
+	case "pause":
+		checkErr(g.Pause(ctx))
+
+	default:
+		fmt.Printf("Command 'group %s' not understood\n", args[0])
+	}
+}
+
+func discoverCommand(c *cli.Context) {
+	log.Debug = c.GlobalBool("debug")
+	ctx, cancel := context.WithTimeout(context.Background(), c.GlobalDuration("timeout"))
+	defer cancel()
+
+	devices, err := discovery.Discover(ctx, c.GlobalDuration("timeout"))
+	checkErr(err)
+
+	if len(devices) == 0 {
+		fmt.Println("No Chromecasts found")
+		return
+	}
+	for _, d := range devices {
+		fmt.Printf("%s\t%s\t%s:%d\n", d.Name, d.Model, d.IP, d.Port)
+	}
 }
 
 func scriptCommand(c *cli.Context) {
@@ -135,10 +249,10 @@ func scriptCommand(c *cli.Context) {
 		commands = append(commands, args)
 	}
 
-	client := connect(ctx, c)
+	client, ip := connect(ctx, c)
 
 	for _, args := range commands {
-		runCommand(ctx, client, args[0], args[1:])
+		runCommand(ctx, client, ip, args[0], args[1:])
 	}
 }
 
@@ -146,7 +260,7 @@ func statusCommand(c *cli.Context) {
 	log.Debug = c.GlobalBool("debug")
 	ctx, cancel := context.WithTimeout(context.Background(), c.GlobalDuration("timeout"))
 	defer cancel()
-	client := connect(ctx, c)
+	client, _ := connect(ctx, c)
 
 	status, err := client.Receiver().GetStatus(ctx)
 	checkErr(err)
@@ -172,6 +286,7 @@ var minArgs = map[string]int{
 	"stop":   0,
 	"quit":   0,
 	"volume": 1,
+	"queue":  1,
 }
 
 var maxArgs = map[string]int{
@@ -180,6 +295,15 @@ var maxArgs = map[string]int{
 	"stop":   0,
 	"quit":   0,
 	"volume": 1,
+	"queue":  100,
+}
+
+var queueActions = map[string]bool{
+	"add":     true,
+	"list":    true,
+	"next":    true,
+	"prev":    true,
+	"shuffle": true,
 }
 
 func checkCommand(cmd string, args []string) bool {
@@ -204,6 +328,16 @@ func checkCommand(cmd string, args []string) bool {
 			return false
 		}
 
+	case "queue":
+		if !queueActions[args[0]] {
+			fmt.Printf("Command 'queue': unknown action %q\n", args[0])
+			return false
+		}
+		if args[0] == "add" && len(args) < 2 {
+			fmt.Printf("Command 'queue add' requires at least one URL\n")
+			return false
+		}
+
 	}
 	return true
 }
@@ -222,16 +356,40 @@ func validateFloat(val string, min, max float64) error {
 	return nil
 }
 
-func runCommand(ctx context.Context, client *cast.Client, cmd string, args []string) {
+func runCommand(ctx context.Context, client *cast.Client, ip net.IP, cmd string, args []string) {
 	switch cmd {
 	case "play":
 		media, err := client.Media(ctx)
 		checkErr(err)
 		url := args[0]
 		contentType := "audio/mpeg"
-		if len(args) > 1 {
+
+		if info, statErr := os.Stat(url); statErr == nil && !info.IsDir() {
+			audioOnly := strings.EqualFold(filepath.Ext(url), ".flac") || strings.EqualFold(filepath.Ext(url), ".opus")
+			transcoder := &mediaserver.FFmpegTranscoder{Audio: audioOnly}
+			server, err := mediaserver.New(url, ip, transcoder)
+			checkErr(err)
+			defer server.Close()
+			url = server.URL()
+			contentType = server.ContentType()
+
+			item := controllers.MediaItem{url, "BUFFERED", contentType}
+			_, err = media.LoadMedia(ctx, item, 0, true, map[string]interface{}{})
+			checkErr(err)
+
+			// The local HTTP server backing url must outlive playback, so
+			// block here until the receiver reports it's done rather than
+			// returning (and closing the server) immediately after LOAD.
+			// This has to run on its own context rather than ctx: ctx is
+			// bound by --timeout (15s by default), far shorter than most
+			// playback, and letting it expire here would tear the server
+			// down out from under the Chromecast mid-stream.
+			waitForPlaybackToFinish(media)
+			return
+		} else if len(args) > 1 {
 			contentType = args[1]
 		}
+
 		item := controllers.MediaItem{url, "BUFFERED", contentType}
 		_, err = media.LoadMedia(ctx, item, 0, true, map[string]interface{}{})
 		checkErr(err)
@@ -265,7 +423,97 @@ func runCommand(ctx context.Context, client *cast.Client, cmd string, args []str
 		_, err := receiver.QuitApp(ctx)
 		checkErr(err)
 
+	case "queue":
+		media, err := client.Media(ctx)
+		checkErr(err)
+		queue := controllers.NewQueue(media)
+		runQueueCommand(ctx, queue, args[0], args[1:])
+
 	default:
 		fmt.Printf("Command '%s' not understood - ignored\n", cmd)
 	}
 }
+
+const playbackPollInterval = 2 * time.Second
+
+// waitForPlaybackToFinish blocks until the receiver's media session leaves
+// the BUFFERING/PLAYING/PAUSED states (or the session disappears entirely),
+// so callers serving media off a local mediaserver.Server don't tear it
+// down while the Chromecast is still pulling from it. It runs its own
+// background context rather than taking one from the caller, since a
+// command's --timeout is usually far shorter than the media itself; Ctrl+C
+// still stops it early.
+func waitForPlaybackToFinish(media *controllers.MediaController) {
+	ticker := time.NewTicker(playbackPollInterval)
+	defer ticker.Stop()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-ticker.C:
+			pollCtx, cancel := context.WithTimeout(context.Background(), playbackPollInterval)
+			status, err := media.GetStatus(pollCtx)
+			cancel()
+			if err != nil {
+				log.Errorf("play: failed to poll media status: %s", err)
+				return
+			}
+			if len(status.Status) == 0 {
+				return
+			}
+			switch status.Status[0].PlayerState {
+			case "PLAYING", "BUFFERING", "PAUSED":
+				continue
+			default:
+				return
+			}
+		case <-sig:
+			return
+		}
+	}
+}
+
+func runQueueCommand(ctx context.Context, queue *controllers.Queue, action string, args []string) {
+	switch action {
+	case "add":
+		items := make([]controllers.QueueItem, len(args))
+		for i, url := range args {
+			items[i] = controllers.QueueItem{
+				Media:    controllers.MediaItem{url, "BUFFERED", "audio/mpeg"},
+				Autoplay: true,
+			}
+		}
+		_, err := queue.Append(ctx, items...)
+		checkErr(err)
+
+	case "list":
+		status, err := queue.GetStatus(ctx)
+		checkErr(err)
+		if len(status.Status) == 0 {
+			return
+		}
+		entry := status.Status[0]
+		for _, item := range entry.Items {
+			current := ""
+			if entry.CurrentItemId != nil && item.ItemId != nil && *entry.CurrentItemId == *item.ItemId {
+				current = "* "
+			}
+			fmt.Printf("%s%s\n", current, item.Media.ContentId)
+		}
+
+	case "next":
+		_, err := queue.Next(ctx)
+		checkErr(err)
+
+	case "prev":
+		_, err := queue.Prev(ctx)
+		checkErr(err)
+
+	case "shuffle":
+		_, err := queue.SetRepeatMode(ctx, controllers.RepeatAllAndShuffle)
+		checkErr(err)
+	}
+}
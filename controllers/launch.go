@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/barnybug/go-cast/api"
+	"github.com/barnybug/go-cast/net"
+)
+
+const receiverLaunch = "LAUNCH"
+
+type launchRequest struct {
+	net.PayloadHeaders
+	AppId string `json:"appId"`
+}
+
+// Launch starts the receiver app identified by appId. It replies with the
+// resulting RECEIVER_STATUS, the same as GetStatus, so callers can find the
+// launched app's transportId once it appears in status.Applications.
+func (r *ReceiverController) Launch(ctx context.Context, appId string) (*api.CastMessage, error) {
+	return r.channel.Request(ctx, &launchRequest{
+		PayloadHeaders: net.PayloadHeaders{Type: receiverLaunch},
+		AppId:          appId,
+	})
+}
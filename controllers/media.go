@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	"golang.org/x/net/context"
+
+	"github.com/barnybug/go-cast/api"
+	"github.com/barnybug/go-cast/net"
+)
+
+const (
+	namespaceMedia = "urn:x-cast:com.google.cast.media"
+
+	mediaLoad      = "LOAD"
+	mediaPause     = "PAUSE"
+	mediaStop      = "STOP"
+	mediaSeek      = "SEEK"
+	mediaGetStatus = "GET_STATUS"
+)
+
+// MediaItem describes a single piece of media, e.g. to LoadMedia.
+type MediaItem struct {
+	ContentId   string `json:"contentId"`
+	StreamType  string `json:"streamType"`
+	ContentType string `json:"contentType"`
+}
+
+// MediaStatus is one entry of a MEDIA_STATUS message's status list. Queue
+// state (Items/CurrentItemId/RepeatMode) rides on the same entry as the
+// playback fields - the receiver doesn't split them into a separate
+// message - so Queue decodes this same struct rather than its own.
+type MediaStatus struct {
+	MediaSessionId int     `json:"mediaSessionId"`
+	PlaybackRate   float64 `json:"playbackRate"`
+	PlayerState    string  `json:"playerState"`
+	CurrentTime    float64 `json:"currentTime"`
+	ItemId         int     `json:"itemId"`
+
+	Items         []QueueItem `json:"items,omitempty"`
+	CurrentItemId *int        `json:"currentItemId,omitempty"`
+	RepeatMode    RepeatMode  `json:"repeatMode,omitempty"`
+}
+
+// MediaStatusResponse is the payload of a MEDIA_STATUS message.
+type MediaStatusResponse struct {
+	net.PayloadHeaders
+	Status []MediaStatus `json:"status"`
+}
+
+type loadRequest struct {
+	net.PayloadHeaders
+	Media       MediaItem   `json:"media"`
+	CurrentTime float64     `json:"currentTime"`
+	Autoplay    bool        `json:"autoplay"`
+	CustomData  interface{} `json:"customData"`
+}
+
+type mediaRequest struct {
+	net.PayloadHeaders
+}
+
+type seekRequest struct {
+	net.PayloadHeaders
+	CurrentTime float64 `json:"currentTime"`
+}
+
+// MediaController wraps the Chromecast media namespace for a single running
+// receiver app session.
+type MediaController struct {
+	channel *net.Channel
+}
+
+// NewMediaController opens the media namespace channel against the running
+// app identified by destinationId (its transportId).
+func NewMediaController(conn *net.Connection, sourceId, destinationId string) *MediaController {
+	return &MediaController{
+		channel: net.NewChannel(conn, sourceId, destinationId, namespaceMedia),
+	}
+}
+
+// Channel exposes the underlying namespace channel, so the reconnect
+// supervisor can replay requests left in flight across a reconnect.
+func (m *MediaController) Channel() *net.Channel {
+	return m.channel
+}
+
+// LoadMedia starts playback of item.
+func (m *MediaController) LoadMedia(ctx context.Context, item MediaItem, currentTime float64, autoplay bool, customData interface{}) (*api.CastMessage, error) {
+	return m.channel.Request(ctx, &loadRequest{
+		PayloadHeaders: net.PayloadHeaders{Type: mediaLoad},
+		Media:          item,
+		CurrentTime:    currentTime,
+		Autoplay:       autoplay,
+		CustomData:     customData,
+	})
+}
+
+// Pause pauses the current media session.
+func (m *MediaController) Pause(ctx context.Context) (*api.CastMessage, error) {
+	return m.channel.Request(ctx, &mediaRequest{PayloadHeaders: net.PayloadHeaders{Type: mediaPause}})
+}
+
+// Stop ends the current media session.
+func (m *MediaController) Stop(ctx context.Context) (*api.CastMessage, error) {
+	return m.channel.Request(ctx, &mediaRequest{PayloadHeaders: net.PayloadHeaders{Type: mediaStop}})
+}
+
+// Seek jumps playback to currentTime, in seconds.
+func (m *MediaController) Seek(ctx context.Context, currentTime float64) (*api.CastMessage, error) {
+	return m.channel.Request(ctx, &seekRequest{
+		PayloadHeaders: net.PayloadHeaders{Type: mediaSeek},
+		CurrentTime:    currentTime,
+	})
+}
+
+// GetStatus fetches the current media status.
+func (m *MediaController) GetStatus(ctx context.Context) (*MediaStatusResponse, error) {
+	message, err := m.channel.Request(ctx, &mediaRequest{PayloadHeaders: net.PayloadHeaders{Type: mediaGetStatus}})
+	if err != nil {
+		return nil, err
+	}
+	status := &MediaStatusResponse{}
+	if err := json.Unmarshal([]byte(*message.PayloadUtf8), status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	"golang.org/x/net/context"
+
+	"github.com/barnybug/go-cast/api"
+	"github.com/barnybug/go-cast/net"
+)
+
+const (
+	queueLoad      = "QUEUE_LOAD"
+	queueInsert    = "QUEUE_INSERT"
+	queueUpdate    = "QUEUE_UPDATE"
+	queueRemove    = "QUEUE_REMOVE"
+	queueReorder   = "QUEUE_REORDER"
+	queueNext      = "QUEUE_NEXT"
+	queuePrev      = "QUEUE_PREV"
+	queueGetStatus = "GET_STATUS"
+)
+
+// RepeatMode controls how a queue behaves once it reaches its last item.
+type RepeatMode string
+
+const (
+	RepeatOff           RepeatMode = "REPEAT_OFF"
+	RepeatAll           RepeatMode = "REPEAT_ALL"
+	RepeatSingle        RepeatMode = "REPEAT_SINGLE"
+	RepeatAllAndShuffle RepeatMode = "REPEAT_ALL_AND_SHUFFLE"
+)
+
+// QueueItem is a single entry in a queue, as accepted by QUEUE_LOAD/INSERT.
+type QueueItem struct {
+	ItemId      *int        `json:"itemId,omitempty"`
+	Media       MediaItem   `json:"media"`
+	Autoplay    bool        `json:"autoplay"`
+	PreloadTime float64     `json:"preloadTime,omitempty"`
+	CustomData  interface{} `json:"customData,omitempty"`
+}
+
+type queueLoadRequest struct {
+	net.PayloadHeaders
+	Items      []QueueItem `json:"items"`
+	RepeatMode RepeatMode  `json:"repeatMode"`
+	StartIndex int         `json:"startIndex"`
+}
+
+type queueInsertRequest struct {
+	net.PayloadHeaders
+	Items        []QueueItem `json:"items"`
+	InsertBefore *int        `json:"insertBefore,omitempty"`
+}
+
+type queueRemoveRequest struct {
+	net.PayloadHeaders
+	ItemIds []int `json:"itemIds"`
+}
+
+type queueReorderRequest struct {
+	net.PayloadHeaders
+	ItemIds      []int `json:"itemIds"`
+	InsertBefore *int  `json:"insertBefore,omitempty"`
+}
+
+type queueUpdateRequest struct {
+	net.PayloadHeaders
+	Jump       *int       `json:"jump,omitempty"`
+	RepeatMode RepeatMode `json:"repeatMode,omitempty"`
+}
+
+// QueueStatus is the payload of a MEDIA_STATUS message, decoded for its
+// queue-shaped fields. Those fields (items, currentItemId, repeatMode) live
+// on each status[] entry alongside the playback fields MediaStatus already
+// models, not on the envelope itself.
+type QueueStatus struct {
+	net.PayloadHeaders
+	Status []MediaStatus `json:"status"`
+}
+
+// Queue wraps the QUEUE_* messages that ride on a Chromecast media session,
+// giving callers a playlist-shaped API on top of the lower level media
+// namespace channel.
+type Queue struct {
+	channel *net.Channel
+	updates chan *QueueStatus
+}
+
+// NewQueue builds a Queue riding on media's existing session channel, so
+// QUEUE_* requests reach the same receiver app instance that LoadMedia
+// launched.
+func NewQueue(media *MediaController) *Queue {
+	q := &Queue{
+		channel: media.channel,
+		updates: make(chan *QueueStatus, 8),
+	}
+	q.channel.OnMessage("MEDIA_STATUS", q.onStatus)
+	return q
+}
+
+// Updates returns a channel that fires whenever a MEDIA_STATUS message
+// reports the queue's current/upcoming item, so callers can pre-buffer. The
+// channel holds up to 8 unread updates; further ones are dropped until the
+// caller reads.
+func (q *Queue) Updates() <-chan *QueueStatus {
+	return q.updates
+}
+
+func (q *Queue) onStatus(message *api.CastMessage) {
+	status := &QueueStatus{}
+	if err := json.Unmarshal([]byte(*message.PayloadUtf8), status); err != nil {
+		return
+	}
+	if len(status.Status) == 0 {
+		return
+	}
+	entry := status.Status[0]
+	if len(entry.Items) == 0 && entry.CurrentItemId == nil {
+		return
+	}
+	select {
+	case q.updates <- status:
+	default:
+	}
+}
+
+// Load replaces the current queue with items, starting playback at
+// startIndex under the given repeat mode.
+func (q *Queue) Load(ctx context.Context, items []QueueItem, repeatMode RepeatMode, startIndex int) (*api.CastMessage, error) {
+	return q.channel.Request(ctx, &queueLoadRequest{
+		PayloadHeaders: net.PayloadHeaders{Type: queueLoad},
+		Items:          items,
+		RepeatMode:     repeatMode,
+		StartIndex:     startIndex,
+	})
+}
+
+// GetStatus fetches the current queue state directly, rather than waiting
+// for the next unsolicited MEDIA_STATUS push on Updates.
+func (q *Queue) GetStatus(ctx context.Context) (*QueueStatus, error) {
+	message, err := q.channel.Request(ctx, &net.PayloadHeaders{Type: queueGetStatus})
+	if err != nil {
+		return nil, err
+	}
+	status := &QueueStatus{}
+	if err := json.Unmarshal([]byte(*message.PayloadUtf8), status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Append adds items to the end of the queue, falling back to Load when the
+// receiver has no queue to insert into yet - QUEUE_INSERT is rejected until
+// a queue has been established by QUEUE_LOAD.
+func (q *Queue) Append(ctx context.Context, items ...QueueItem) (*api.CastMessage, error) {
+	status, err := q.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(status.Status) == 0 || len(status.Status[0].Items) == 0 {
+		return q.Load(ctx, items, RepeatOff, 0)
+	}
+	return q.channel.Request(ctx, &queueInsertRequest{
+		PayloadHeaders: net.PayloadHeaders{Type: queueInsert},
+		Items:          items,
+	})
+}
+
+// Insert adds items before the item identified by before.
+func (q *Queue) Insert(ctx context.Context, before int, items ...QueueItem) (*api.CastMessage, error) {
+	return q.channel.Request(ctx, &queueInsertRequest{
+		PayloadHeaders: net.PayloadHeaders{Type: queueInsert},
+		Items:          items,
+		InsertBefore:   &before,
+	})
+}
+
+// Remove deletes the items with the given ids from the queue.
+func (q *Queue) Remove(ctx context.Context, ids ...int) (*api.CastMessage, error) {
+	return q.channel.Request(ctx, &queueRemoveRequest{
+		PayloadHeaders: net.PayloadHeaders{Type: queueRemove},
+		ItemIds:        ids,
+	})
+}
+
+// Reorder moves ids to immediately before the item identified by before. A
+// nil before moves them to the end of the queue.
+func (q *Queue) Reorder(ctx context.Context, ids []int, before *int) (*api.CastMessage, error) {
+	return q.channel.Request(ctx, &queueReorderRequest{
+		PayloadHeaders: net.PayloadHeaders{Type: queueReorder},
+		ItemIds:        ids,
+		InsertBefore:   before,
+	})
+}
+
+// Jump skips forward (n > 0) or backward (n < 0) n items in the queue.
+func (q *Queue) Jump(ctx context.Context, n int) (*api.CastMessage, error) {
+	return q.channel.Request(ctx, &queueUpdateRequest{
+		PayloadHeaders: net.PayloadHeaders{Type: queueUpdate},
+		Jump:           &n,
+	})
+}
+
+// Next advances to the next item in the queue.
+func (q *Queue) Next(ctx context.Context) (*api.CastMessage, error) {
+	return q.channel.Request(ctx, &net.PayloadHeaders{Type: queueNext})
+}
+
+// Prev returns to the previous item in the queue.
+func (q *Queue) Prev(ctx context.Context) (*api.CastMessage, error) {
+	return q.channel.Request(ctx, &net.PayloadHeaders{Type: queuePrev})
+}
+
+// SetRepeatMode changes how the queue behaves once it reaches its last item.
+func (q *Queue) SetRepeatMode(ctx context.Context, mode RepeatMode) (*api.CastMessage, error) {
+	return q.channel.Request(ctx, &queueUpdateRequest{
+		PayloadHeaders: net.PayloadHeaders{Type: queueUpdate},
+		RepeatMode:     mode,
+	})
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	"golang.org/x/net/context"
+
+	"github.com/barnybug/go-cast/api"
+	"github.com/barnybug/go-cast/net"
+)
+
+const (
+	namespaceReceiver = "urn:x-cast:com.google.cast.receiver"
+
+	receiverGetStatus = "GET_STATUS"
+	receiverSetVolume = "SET_VOLUME"
+	receiverStop      = "STOP"
+)
+
+// Volume is the Chromecast's system volume.
+type Volume struct {
+	Level *float64 `json:"level,omitempty"`
+	Muted *bool    `json:"muted,omitempty"`
+}
+
+// Application describes a receiver app that's currently running.
+type Application struct {
+	AppId       string  `json:"appId"`
+	DisplayName *string `json:"displayName,omitempty"`
+	StatusText  *string `json:"statusText,omitempty"`
+	TransportId string  `json:"transportId"`
+	SessionId   string  `json:"sessionId"`
+}
+
+// Status is the "status" object of a RECEIVER_STATUS message.
+type Status struct {
+	net.PayloadHeaders
+	Applications []Application `json:"applications,omitempty"`
+	Volume       *Volume       `json:"volume,omitempty"`
+}
+
+type receiverRequest struct {
+	net.PayloadHeaders
+}
+
+type volumeRequest struct {
+	net.PayloadHeaders
+	Volume *Volume `json:"volume"`
+}
+
+// ReceiverController wraps the Chromecast receiver namespace: running
+// applications and system volume.
+type ReceiverController struct {
+	channel *net.Channel
+}
+
+// NewReceiverController opens the receiver namespace channel against
+// destinationId (normally "receiver-0").
+func NewReceiverController(conn *net.Connection, sourceId, destinationId string) *ReceiverController {
+	return &ReceiverController{
+		channel: net.NewChannel(conn, sourceId, destinationId, namespaceReceiver),
+	}
+}
+
+// Channel exposes the underlying namespace channel, so the reconnect
+// supervisor can replay requests left in flight across a reconnect.
+func (r *ReceiverController) Channel() *net.Channel {
+	return r.channel
+}
+
+// GetStatus fetches the current receiver status: running applications and
+// volume.
+func (r *ReceiverController) GetStatus(ctx context.Context) (*Status, error) {
+	message, err := r.channel.Request(ctx, &receiverRequest{PayloadHeaders: net.PayloadHeaders{Type: receiverGetStatus}})
+	if err != nil {
+		return nil, err
+	}
+	response := &struct {
+		net.PayloadHeaders
+		Status *Status `json:"status"`
+	}{}
+	if err := json.Unmarshal([]byte(*message.PayloadUtf8), response); err != nil {
+		return nil, err
+	}
+	if response.Status == nil {
+		return &Status{}, nil
+	}
+	return response.Status, nil
+}
+
+// SetVolume sets the Chromecast's system volume.
+func (r *ReceiverController) SetVolume(ctx context.Context, volume *Volume) (*api.CastMessage, error) {
+	return r.channel.Request(ctx, &volumeRequest{
+		PayloadHeaders: net.PayloadHeaders{Type: receiverSetVolume},
+		Volume:         volume,
+	})
+}
+
+// QuitApp closes whichever app is currently running on the receiver.
+func (r *ReceiverController) QuitApp(ctx context.Context) (*api.CastMessage, error) {
+	return r.channel.Request(ctx, &receiverRequest{PayloadHeaders: net.PayloadHeaders{Type: receiverStop}})
+}
@@ -0,0 +1,176 @@
+// Package discovery finds Chromecast devices on the local network using
+// mDNS/DNS-SD and decodes the TXT records they advertise under the
+// "_googlecast._tcp" service type.
+package discovery
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/barnybug/go-cast/log"
+	"github.com/hashicorp/mdns"
+)
+
+const service = "_googlecast._tcp"
+
+// Device describes a Chromecast found on the network.
+type Device struct {
+	Name         string // friendly name, e.g. "Kitchen"
+	Model        string
+	UUID         string
+	IP           net.IP
+	Port         int
+	Capabilities map[string]string
+}
+
+// EventType distinguishes the two kinds of DeviceEvent emitted by Watch.
+type EventType int
+
+const (
+	// DeviceAdded is emitted the first time a device is seen, and whenever
+	// it reappears after being removed.
+	DeviceAdded EventType = iota
+	// DeviceRemoved is emitted once a device stops responding to queries.
+	DeviceRemoved
+)
+
+// DeviceEvent is sent on the channel returned by Watch.
+type DeviceEvent struct {
+	Type   EventType
+	Device Device
+}
+
+// Discover performs a single mDNS sweep for timeout and returns every
+// Chromecast that answered.
+func Discover(ctx context.Context, timeout time.Duration) ([]Device, error) {
+	entries := make(chan *mdns.ServiceEntry, 16)
+	devices := []Device{}
+	done := make(chan error, 1)
+
+	go func() {
+		done <- mdns.Query(&mdns.QueryParam{
+			Service: service,
+			Timeout: timeout,
+			Entries: entries,
+		})
+		close(entries)
+	}()
+
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				return devices, nil
+			}
+			devices = append(devices, parseEntry(entry))
+		case err := <-done:
+			if err != nil {
+				return devices, err
+			}
+		case <-ctx.Done():
+			return devices, ctx.Err()
+		}
+	}
+}
+
+// Watch continuously polls mDNS and reports devices as they come and go. It
+// stops when ctx is cancelled.
+func Watch(ctx context.Context) <-chan DeviceEvent {
+	events := make(chan DeviceEvent)
+
+	go func() {
+		defer close(events)
+		seen := map[string]Device{}
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+
+		poll := func() {
+			found, err := Discover(ctx, 3*time.Second)
+			if err != nil {
+				log.Errorf("discovery: query failed: %s", err)
+				return
+			}
+
+			current := map[string]Device{}
+			for _, d := range found {
+				current[d.UUID] = d
+				if _, ok := seen[d.UUID]; !ok {
+					events <- DeviceEvent{Type: DeviceAdded, Device: d}
+				}
+			}
+			for uuid, d := range seen {
+				if _, ok := current[uuid]; !ok {
+					events <- DeviceEvent{Type: DeviceRemoved, Device: d}
+				}
+			}
+			seen = current
+		}
+
+		poll()
+		for {
+			select {
+			case <-ticker.C:
+				poll()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events
+}
+
+// Find looks up a single device by friendly name, returning an error if it
+// can't be found within timeout.
+func Find(ctx context.Context, name string, timeout time.Duration) (*Device, error) {
+	devices, err := Discover(ctx, timeout)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range devices {
+		if strings.EqualFold(d.Name, name) {
+			return &d, nil
+		}
+	}
+	return nil, fmt.Errorf("discovery: no Chromecast named %q found", name)
+}
+
+func parseEntry(entry *mdns.ServiceEntry) Device {
+	device := Device{
+		IP:           entry.AddrV4,
+		Port:         entry.Port,
+		Capabilities: map[string]string{},
+	}
+	if device.IP == nil {
+		device.IP = entry.AddrV6
+	}
+
+	for _, field := range entry.InfoFields {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := parts[0], parts[1]
+		switch key {
+		case "fn":
+			device.Name = value
+		case "md":
+			device.Model = value
+		case "id":
+			device.UUID = value
+		case "ca":
+			if _, err := strconv.Atoi(value); err == nil {
+				device.Capabilities[key] = value
+			}
+		default:
+			device.Capabilities[key] = value
+		}
+	}
+
+	return device
+}
@@ -0,0 +1,228 @@
+// Package group fans media commands out to several Chromecasts at once and
+// keeps their playback positions aligned, as a pure-Go alternative to a cast
+// group pre-paired through the Google Home app.
+package group
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/barnybug/go-cast"
+	"github.com/barnybug/go-cast/controllers"
+	"github.com/barnybug/go-cast/log"
+)
+
+const (
+	driftCheckInterval = 2 * time.Second
+	// maxDrift is how far a member's currentTime may differ from the group
+	// leader's, ahead or behind, before a corrective seek is issued.
+	maxDrift = 300 * time.Millisecond
+)
+
+// Group is N Chromecast clients driven as one, with a background loop that
+// corrects drift between them.
+type Group struct {
+	members []*cast.Client
+
+	mutex  sync.Mutex
+	cancel context.CancelFunc
+}
+
+// New builds a Group over clients, which must already be connected. The
+// first client is treated as the drift-correction leader: the others are
+// seeked to match its reported currentTime.
+func New(clients ...*cast.Client) *Group {
+	return &Group{members: clients}
+}
+
+// Play loads item on every member and starts a background loop that keeps
+// them within maxDrift of each other until Close is called.
+func (g *Group) Play(ctx context.Context, item controllers.MediaItem) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(g.members))
+
+	for i, client := range g.members {
+		wg.Add(1)
+		go func(i int, client *cast.Client) {
+			defer wg.Done()
+			media, err := client.Media(ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			_, errs[i] = media.LoadMedia(ctx, item, 0, true, map[string]interface{}{})
+		}(i, client)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	g.mutex.Lock()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	// driftCtx is derived from Background, not ctx: the correction loop must
+	// keep running for as long as the group exists, not just until whatever
+	// short-lived context the caller used to issue this Play expires. Only
+	// Close (or the next Play) should stop it.
+	driftCtx, cancel := context.WithCancel(context.Background())
+	g.cancel = cancel
+	g.mutex.Unlock()
+
+	go g.correctDrift(driftCtx)
+
+	return nil
+}
+
+// Pause pauses every member.
+func (g *Group) Pause(ctx context.Context) error {
+	return g.forEach(ctx, func(ctx context.Context, media *controllers.MediaController) error {
+		_, err := media.Pause(ctx)
+		return err
+	})
+}
+
+// SetVolume sets a per-device volume, keyed by the same *cast.Client
+// pointers the Group was built with.
+func (g *Group) SetVolume(ctx context.Context, levels map[*cast.Client]*controllers.Volume) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(g.members))
+
+	for i, client := range g.members {
+		volume, ok := levels[client]
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, client *cast.Client, volume *controllers.Volume) {
+			defer wg.Done()
+			_, errs[i] = client.Receiver().SetVolume(ctx, volume)
+		}(i, client, volume)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the drift-correction loop. It does not disconnect members.
+func (g *Group) Close() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.cancel != nil {
+		g.cancel()
+		g.cancel = nil
+	}
+}
+
+func (g *Group) forEach(ctx context.Context, fn func(ctx context.Context, media *controllers.MediaController) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(g.members))
+
+	for i, client := range g.members {
+		wg.Add(1)
+		go func(i int, client *cast.Client) {
+			defer wg.Done()
+			media, err := client.Media(ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = fn(ctx, media)
+		}(i, client)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// correctDrift periodically compares every member's reported currentTime
+// against the leader's and issues a corrective Seek to any member more than
+// maxDrift ahead of or behind it.
+func (g *Group) correctDrift(ctx context.Context) {
+	ticker := time.NewTicker(driftCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			g.alignMembers(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (g *Group) alignMembers(ctx context.Context) {
+	if len(g.members) < 2 {
+		return
+	}
+
+	times := make([]float64, len(g.members))
+	valid := make([]bool, len(g.members))
+	for i, client := range g.members {
+		media, err := client.Media(ctx)
+		if err != nil {
+			log.Errorf("group: failed to fetch media controller: %s", err)
+			continue
+		}
+		status, err := media.GetStatus(ctx)
+		if err != nil {
+			log.Errorf("group: failed to fetch media status: %s", err)
+			continue
+		}
+		if len(status.Status) == 0 || status.Status[0].PlayerState != "PLAYING" {
+			continue
+		}
+		times[i] = status.Status[0].CurrentTime
+		valid[i] = true
+	}
+
+	leaderIndex := -1
+	for i, ok := range valid {
+		if ok {
+			leaderIndex = i
+			break
+		}
+	}
+	if leaderIndex == -1 {
+		return
+	}
+	leader := times[leaderIndex]
+
+	for i := 0; i < len(g.members); i++ {
+		if i == leaderIndex || !valid[i] {
+			continue
+		}
+		drift := leader - times[i]
+		if drift < 0 {
+			drift = -drift
+		}
+		if time.Duration(drift*float64(time.Second)) <= maxDrift {
+			continue
+		}
+
+		media, err := g.members[i].Media(ctx)
+		if err != nil {
+			continue
+		}
+		if _, err := media.Seek(ctx, leader); err != nil {
+			log.Errorf("group: corrective seek failed: %s", err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package mediaserver serves a local file over HTTP so it can be handed to
+// a Chromecast as a LoadMedia URL, optionally transcoding it on the fly when
+// the container/codec isn't one Chromecast supports natively.
+package mediaserver
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/barnybug/go-cast/log"
+)
+
+// extraTypes covers extensions Chromecast plays natively but that aren't
+// always present in the system mime database.
+var extraTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".wav":  "audio/wav",
+	".mp4":  "video/mp4",
+	".webm": "video/webm",
+}
+
+// Server serves a single local file to whichever Chromecast requests it.
+type Server struct {
+	path       string
+	transcoder Transcoder
+	listener   net.Listener
+	http       *http.Server
+}
+
+// New starts an HTTP server bound to the local interface used to reach
+// target, serving path. If path needs transcoding (see NeedsTranscode) and
+// transcoder is non-nil, requests are piped through it instead of being
+// served as-is.
+func New(path string, target net.IP, transcoder Transcoder) (*Server, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	bind, err := outboundAddr(target)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(bind.String(), "0"))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		path:       path,
+		transcoder: transcoder,
+		listener:   listener,
+	}
+	s.http = &http.Server{Handler: s}
+	go func() {
+		if err := s.http.Serve(listener); err != nil {
+			log.Errorf("mediaserver: stopped: %s", err)
+		}
+	}()
+
+	return s, nil
+}
+
+// URL is the address the file is reachable at, to pass to LoadMedia.
+func (s *Server) URL() string {
+	return fmt.Sprintf("http://%s/%s", s.listener.Addr(), filepath.Base(s.path))
+}
+
+// ContentType is the MIME type that will be reported to the Chromecast.
+func (s *Server) ContentType() string {
+	if s.transcoder != nil && NeedsTranscode(s.path) {
+		return s.transcoder.ContentType()
+	}
+	return contentType(s.path)
+}
+
+// Close stops the server. It does not block on in-flight requests draining.
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.transcoder != nil && NeedsTranscode(s.path) {
+		w.Header().Set("Content-Type", s.transcoder.ContentType())
+		if err := s.transcoder.Transcode(r.Context(), s.path, w); err != nil {
+			log.Errorf("mediaserver: transcode failed: %s", err)
+		}
+		return
+	}
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType(s.path))
+	http.ServeContent(w, r, filepath.Base(s.path), info.ModTime(), file)
+}
+
+func contentType(path string) string {
+	ext := filepath.Ext(path)
+	if t, ok := extraTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// outboundAddr returns the local address the kernel would pick to reach
+// target, so the server binds to the interface actually facing the
+// Chromecast rather than an arbitrary/unreachable one.
+func outboundAddr(target net.IP) (net.IP, error) {
+	conn, err := net.Dial("udp", net.JoinHostPort(target.String(), "9"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP, nil
+}
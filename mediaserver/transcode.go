@@ -0,0 +1,76 @@
+package mediaserver
+
+import (
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// unsupportedExts lists containers/codecs a Chromecast can't play natively,
+// which therefore need remuxing or transcoding before LoadMedia will work.
+var unsupportedExts = map[string]bool{
+	".mkv":  true,
+	".flac": true,
+	".opus": true,
+}
+
+// NeedsTranscode reports whether path's container/extension is one
+// Chromecast can't play natively.
+func NeedsTranscode(path string) bool {
+	return unsupportedExts[strings.ToLower(filepath.Ext(path))]
+}
+
+// Transcoder converts src into a Chromecast-friendly stream, written to w as
+// it becomes available.
+type Transcoder interface {
+	// Transcode writes a transcoded/remuxed stream of src to w. It should
+	// honour ctx cancellation so an aborted HTTP request doesn't leak a
+	// background transcode process.
+	Transcode(ctx context.Context, src string, w io.Writer) error
+	// ContentType is the MIME type Transcode's output will be in.
+	ContentType() string
+}
+
+// FFmpegTranscoder remuxes/transcodes using an external ffmpeg binary,
+// targeting fragmented MP4 (H.264/AAC) for video and MP3 for audio-only
+// sources, which Chromecast plays natively.
+type FFmpegTranscoder struct {
+	// Path to the ffmpeg binary. Defaults to "ffmpeg" (looked up on $PATH)
+	// if empty.
+	Path string
+	// Audio selects an audio-only (MP3) transcode instead of MP4 video.
+	Audio bool
+}
+
+func (t *FFmpegTranscoder) bin() string {
+	if t.Path != "" {
+		return t.Path
+	}
+	return "ffmpeg"
+}
+
+func (t *FFmpegTranscoder) ContentType() string {
+	if t.Audio {
+		return "audio/mpeg"
+	}
+	return "video/mp4"
+}
+
+func (t *FFmpegTranscoder) Transcode(ctx context.Context, src string, w io.Writer) error {
+	args := []string{"-i", src}
+	if t.Audio {
+		args = append(args, "-vn", "-f", "mp3", "-")
+	} else {
+		args = append(args,
+			"-c:v", "libx264", "-c:a", "aac",
+			"-movflags", "frag_keyframe+empty_moov",
+			"-f", "mp4", "-")
+	}
+
+	cmd := exec.CommandContext(ctx, t.bin(), args...)
+	cmd.Stdout = w
+	return cmd.Run()
+}
@@ -1,6 +1,7 @@
 package net
 
 import (
+	"encoding/json"
 	"sync"
 	"sync/atomic"
 
@@ -18,10 +19,18 @@ type Channel struct {
 	_             int32
 	requestId     int64
 	mutex         sync.Mutex
-	inFlight      map[int]chan *api.CastMessage
+	inFlight      map[int]*pendingRequest
 	listeners     []channelListener
 }
 
+// pendingRequest tracks a Request/RequestRaw call that hasn't been answered
+// yet, keeping the original payload around so it can be resent with the
+// same requestId if the connection drops and reconnects mid-flight.
+type pendingRequest struct {
+	response chan *api.CastMessage
+	payload  interface{}
+}
+
 type channelListener struct {
 	responseType string
 	callback     func(*api.CastMessage)
@@ -33,14 +42,16 @@ type Payload interface {
 }
 
 func NewChannel(conn *Connection, sourceId, destinationId, namespace string) *Channel {
-	return &Channel{
+	channel := &Channel{
 		conn:          conn,
 		sourceId:      sourceId,
 		DestinationId: destinationId,
 		namespace:     namespace,
 		listeners:     make([]channelListener, 0),
-		inFlight:      make(map[int]chan *api.CastMessage),
+		inFlight:      make(map[int]*pendingRequest),
 	}
+	conn.RegisterChannel(channel)
+	return channel
 }
 
 func (c *Channel) Message(message *api.CastMessage, headers *PayloadHeaders) {
@@ -55,11 +66,11 @@ func (c *Channel) Message(message *api.CastMessage, headers *PayloadHeaders) {
 
 	if headers.RequestId != nil && *headers.RequestId != 0 {
 		c.mutex.Lock()
-		listener, ok := c.inFlight[*headers.RequestId]
+		pending, ok := c.inFlight[*headers.RequestId]
 		c.mutex.Unlock()
 
 		if ok {
-			listener <- message
+			pending.response <- message
 
 			c.mutex.Lock()
 			delete(c.inFlight, *headers.RequestId)
@@ -89,7 +100,7 @@ func (c *Channel) Request(ctx context.Context, payload Payload) (*api.CastMessag
 	response := make(chan *api.CastMessage)
 
 	c.mutex.Lock()
-	c.inFlight[requestId] = response
+	c.inFlight[requestId] = &pendingRequest{response: response, payload: payload}
 	c.mutex.Unlock()
 
 	err := c.Send(payload)
@@ -112,3 +123,71 @@ func (c *Channel) Request(ctx context.Context, payload Payload) (*api.CastMessag
 		return nil, ctx.Err()
 	}
 }
+
+// RequestRaw behaves like Request, but takes and returns json.RawMessage so
+// callers can drive a custom namespace without defining Go structs for it.
+// msgType is merged into the payload as its "type" field and requestId is
+// assigned and matched the same way Request does it.
+func (c *Channel) RequestRaw(ctx context.Context, msgType string, payload json.RawMessage) (json.RawMessage, error) {
+	requestId := int(atomic.AddInt64(&c.requestId, 1))
+
+	merged := map[string]interface{}{}
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &merged); err != nil {
+			return nil, err
+		}
+	}
+	merged["type"] = msgType
+	merged["requestId"] = requestId
+
+	response := make(chan *api.CastMessage)
+	c.mutex.Lock()
+	c.inFlight[requestId] = &pendingRequest{response: response, payload: merged}
+	c.mutex.Unlock()
+
+	if err := c.Send(merged); err != nil {
+		c.mutex.Lock()
+		delete(c.inFlight, requestId)
+		c.mutex.Unlock()
+		return nil, err
+	}
+
+	select {
+	case reply := <-response:
+		return json.RawMessage(*reply.PayloadUtf8), nil
+	case <-ctx.Done():
+		c.mutex.Lock()
+		delete(c.inFlight, requestId)
+		c.mutex.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// Subscribe registers handler to be called with the raw payload of every
+// message on this channel matching responseType. Unlike OnMessage it decodes
+// the payload for the caller instead of handing back the whole CastMessage.
+func (c *Channel) Subscribe(responseType string, handler func(json.RawMessage)) {
+	c.OnMessage(responseType, func(message *api.CastMessage) {
+		handler(json.RawMessage(*message.PayloadUtf8))
+	})
+}
+
+// Resend re-issues every request still awaiting a reply, keeping their
+// original requestId so the eventual response still matches up with the
+// caller's Request/RequestRaw call. It's called by the connection
+// supervisor once a dropped connection has reconnected, so callers observe
+// a latency spike rather than a hard error.
+func (c *Channel) Resend() {
+	c.mutex.Lock()
+	pending := make([]*pendingRequest, 0, len(c.inFlight))
+	for _, p := range c.inFlight {
+		pending = append(pending, p)
+	}
+	c.mutex.Unlock()
+
+	for _, p := range pending {
+		if err := c.Send(p.payload); err != nil {
+			log.Errorf("channel: failed to resend request after reconnect: %s", err)
+		}
+	}
+}
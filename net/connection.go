@@ -0,0 +1,233 @@
+package net
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+
+	"github.com/barnybug/go-cast/api"
+	"github.com/barnybug/go-cast/log"
+)
+
+const (
+	heartbeatInterval = 5 * time.Second
+	heartbeatTimeout  = 3 * heartbeatInterval
+)
+
+// Connection is the TLS transport Channels ride on: a length-prefixed
+// stream of serialized CastMessages. It also watches the tp-heartbeat
+// PING/PONG traffic so a dropped Chromecast is noticed even when the TCP
+// socket itself hasn't reported an error yet.
+type Connection struct {
+	mutex sync.Mutex
+	conn  net.Conn
+	// stop is closed when conn is torn down, to unwind the read and
+	// heartbeat-watch loops that belong to that socket. It's independent of
+	// any caller context so a short-lived "reconnect" context doesn't cut
+	// the heartbeat watch short.
+	stop chan struct{}
+
+	channels     []*Channel
+	onDisconnect func(error)
+	lastActivity time.Time
+}
+
+// NewConnection builds an unconnected Connection. Call Connect to dial.
+func NewConnection() *Connection {
+	return &Connection{}
+}
+
+// Connect dials host:port over TLS and starts the read and heartbeat-watch
+// loops. Calling Connect again on an existing Connection - as the reconnect
+// supervisor does - redials in place: registered channels and the
+// disconnect callback carry over.
+func (c *Connection) Connect(ctx context.Context, host net.IP, port int) error {
+	dialer := &net.Dialer{}
+	addr := fmt.Sprintf("%s:%d", host, port)
+	tlsConn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan struct{})
+
+	c.mutex.Lock()
+	c.conn = tlsConn
+	c.stop = stop
+	c.lastActivity = time.Now()
+	c.mutex.Unlock()
+
+	go c.readLoop(tlsConn, stop)
+	go c.heartbeatLoop(stop)
+
+	return nil
+}
+
+// OnDisconnect registers the callback invoked, once per dropped connection,
+// when the read loop observes an EOF/read error or the heartbeat watch
+// observes a missed heartbeat.
+func (c *Connection) OnDisconnect(cb func(error)) {
+	c.mutex.Lock()
+	c.onDisconnect = cb
+	c.mutex.Unlock()
+}
+
+// RegisterChannel adds channel to the set that incoming messages are
+// dispatched to. NewChannel calls this automatically.
+func (c *Connection) RegisterChannel(channel *Channel) {
+	c.mutex.Lock()
+	c.channels = append(c.channels, channel)
+	c.mutex.Unlock()
+}
+
+// disconnected tears down the current socket's loops (closing stop, which
+// is a no-op if the other loop already got there first) and reports the
+// drop to the supervisor exactly once.
+func (c *Connection) disconnected(stop chan struct{}, err error) {
+	c.mutex.Lock()
+	alreadyStopped := c.stop != stop
+	if !alreadyStopped {
+		close(stop)
+		c.stop = nil
+	}
+	cb := c.onDisconnect
+	c.mutex.Unlock()
+
+	if alreadyStopped {
+		return
+	}
+	if cb != nil {
+		cb(err)
+	}
+}
+
+func (c *Connection) readLoop(conn net.Conn, stop chan struct{}) {
+	for {
+		message, headers, err := c.receiveMessage(conn)
+		if err != nil {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if err != io.EOF {
+				log.Errorf("connection: read failed: %s", err)
+			}
+			c.disconnected(stop, err)
+			return
+		}
+
+		c.mutex.Lock()
+		c.lastActivity = time.Now()
+		channels := make([]*Channel, len(c.channels))
+		copy(channels, c.channels)
+		c.mutex.Unlock()
+
+		if headers.Type == "PING" {
+			c.sendPong()
+			continue
+		}
+
+		for _, channel := range channels {
+			channel.Message(message, headers)
+		}
+	}
+}
+
+// heartbeatLoop treats a receiver that's gone quiet for longer than
+// heartbeatTimeout - missing its tp-heartbeat PING - as disconnected, even
+// though the TCP socket may not have reported an error (a common symptom of
+// a Chromecast dropping off wifi).
+func (c *Connection) heartbeatLoop(stop chan struct{}) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.mutex.Lock()
+			idle := time.Since(c.lastActivity)
+			c.mutex.Unlock()
+			if idle > heartbeatTimeout {
+				c.disconnected(stop, fmt.Errorf("connection: no tp-heartbeat PING in %s", idle))
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (c *Connection) sendPong() {
+	err := c.Send(&PayloadHeaders{Type: "PONG"}, "sender-0", "receiver-0", "urn:x-cast:com.google.cast.tp.heartbeat")
+	if err != nil {
+		log.Errorf("connection: failed to send heartbeat PONG: %s", err)
+	}
+}
+
+func (c *Connection) receiveMessage(conn net.Conn) (*api.CastMessage, *PayloadHeaders, error) {
+	var length uint32
+	if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+		return nil, nil, err
+	}
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return nil, nil, err
+	}
+
+	message := &api.CastMessage{}
+	if err := proto.Unmarshal(buf, message); err != nil {
+		return nil, nil, err
+	}
+
+	headers := &PayloadHeaders{}
+	if err := json.Unmarshal([]byte(*message.PayloadUtf8), headers); err != nil {
+		return nil, nil, err
+	}
+
+	return message, headers, nil
+}
+
+// Send serializes payload as the message body on namespace, from sourceId
+// to destinationId.
+func (c *Connection) Send(payload interface{}, sourceId, destinationId, namespace string) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	payloadUtf8 := string(data)
+
+	protocolVersion := api.CastMessage_CASTV2_1_0
+	payloadType := api.CastMessage_STRING
+	message := &api.CastMessage{
+		ProtocolVersion: &protocolVersion,
+		SourceId:        &sourceId,
+		DestinationId:   &destinationId,
+		Namespace:       &namespace,
+		PayloadType:     &payloadType,
+		PayloadUtf8:     &payloadUtf8,
+	}
+
+	buf, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := binary.Write(c.conn, binary.BigEndian, uint32(len(buf))); err != nil {
+		return err
+	}
+	_, err = c.conn.Write(buf)
+	return err
+}
@@ -0,0 +1,152 @@
+package net
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/barnybug/go-cast/log"
+)
+
+// State describes the lifecycle of the supervised connection.
+type State int
+
+const (
+	StateDisconnected State = iota
+	StateConnecting
+	StateConnected
+)
+
+func (s State) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateConnecting:
+		return "connecting"
+	default:
+		return "disconnected"
+	}
+}
+
+const (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 30 * time.Second
+)
+
+// Supervisor watches a Connection and transparently reconnects it with
+// exponential backoff when it drops (EOF, or a tp-heartbeat PING/PONG
+// timeout), replaying the CONNECT+LAUNCH/JOIN handshake and re-sending any
+// requests that were still in flight on every registered Channel.
+type Supervisor struct {
+	conn      *Connection
+	host      net.IP
+	port      int
+	handshake func(ctx context.Context) error
+
+	mutex    sync.Mutex
+	channels []*Channel
+	state    State
+	states   chan State
+}
+
+// NewSupervisor builds a Supervisor around conn, which is already connected
+// to host:port. handshake is called after every successful reconnect to
+// restore the session (CONNECT, then LAUNCH/JOIN as appropriate) before
+// pending requests are replayed.
+func NewSupervisor(conn *Connection, host net.IP, port int, handshake func(ctx context.Context) error) *Supervisor {
+	return &Supervisor{
+		conn:      conn,
+		host:      host,
+		port:      port,
+		handshake: handshake,
+		state:     StateConnected,
+		states:    make(chan State, 1),
+	}
+}
+
+// Register adds channel to the set that gets told to resend its in-flight
+// requests after a reconnect.
+func (s *Supervisor) Register(channel *Channel) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.channels = append(s.channels, channel)
+}
+
+// State returns a channel of connection state transitions. It's buffered by
+// one and only ever holds the most recent state, so slow readers see where
+// things currently stand rather than a backlog of history.
+func (s *Supervisor) State() <-chan State {
+	return s.states
+}
+
+func (s *Supervisor) setState(state State) {
+	s.mutex.Lock()
+	s.state = state
+	s.mutex.Unlock()
+
+	select {
+	case <-s.states:
+	default:
+	}
+	s.states <- state
+}
+
+// Disconnected is called, via the Connection's OnDisconnect callback, when
+// the read loop observes an EOF or the heartbeat watch a missed heartbeat. It drives a reconnect loop with exponential
+// backoff until ctx is cancelled or the connection comes back.
+func (s *Supervisor) Disconnected(ctx context.Context) {
+	s.setState(StateDisconnected)
+
+	backoff := initialBackoff
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		s.setState(StateConnecting)
+		log.Printf("connection: reconnecting...")
+
+		connectCtx, cancel := context.WithTimeout(ctx, maxBackoff)
+		err := s.conn.Connect(connectCtx, s.host, s.port)
+		if err == nil {
+			err = s.handshake(connectCtx)
+		}
+		cancel()
+
+		if err == nil {
+			s.setState(StateConnected)
+			s.resendAll()
+			return
+		}
+
+		log.Errorf("connection: reconnect failed: %s", err)
+
+		jitter := time.Duration(rand.Int63n(int64(backoff) / 2))
+		select {
+		case <-time.After(backoff + jitter):
+		case <-ctx.Done():
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (s *Supervisor) resendAll() {
+	s.mutex.Lock()
+	channels := make([]*Channel, len(s.channels))
+	copy(channels, s.channels)
+	s.mutex.Unlock()
+
+	for _, channel := range channels {
+		channel.Resend()
+	}
+}
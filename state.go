@@ -0,0 +1,23 @@
+package cast
+
+import (
+	"github.com/barnybug/go-cast/net"
+)
+
+// State describes the lifecycle of a Client's connection to its
+// Chromecast.
+type State = net.State
+
+const (
+	StateDisconnected = net.StateDisconnected
+	StateConnecting   = net.StateConnecting
+	StateConnected    = net.StateConnected
+)
+
+// State returns a channel of connection state transitions, so CLI/script
+// users can react to a dropped/reconnecting Chromecast instead of just
+// seeing requests block. The underlying connection reconnects and replays
+// its handshake automatically; this is for observability, not control.
+func (c *Client) State() <-chan State {
+	return c.supervisor.State()
+}